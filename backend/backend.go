@@ -0,0 +1,23 @@
+// Package backend defines the interface that every confita backend must
+// implement and the sentinel errors shared across all of them.
+package backend
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by a backend when it doesn't hold a value for
+// the requested key. The loader treats it as "try the next backend"
+// rather than a hard failure.
+var ErrNotFound = errors.New("key not found")
+
+// Backend fetches configuration values by key.
+type Backend interface {
+	// Get returns the raw value stored under key, or ErrNotFound if the
+	// backend has nothing for it.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Name returns a short, human readable identifier for the backend,
+	// used in error messages.
+	Name() string
+}
@@ -0,0 +1,176 @@
+// Package flags implements a confita backend that reads configuration
+// from command line flags.
+package flags
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/HnH/confita"
+	"github.com/HnH/confita/backend"
+	"github.com/HnH/confita/backend/flags/internal/fieldvalue"
+	"github.com/HnH/confita/backend/flags/internal/usage"
+)
+
+// ErrHelpRequested is returned by Load when the command line asked for
+// help (`-h`/`--help`). The usage message has already been written to
+// the backend's usage writer by the time callers see it; they should
+// treat it as a signal to exit cleanly rather than as a failure.
+var ErrHelpRequested = errors.New("flags: help requested")
+
+// Backend is a confita backend that resolves keys from command line
+// flags. It implements confita.StructBackend: on Load it registers one
+// flag per tagged struct field (plus its `short=` alias, if any) onto
+// its FlagSet and parses its args before any key is resolved.
+type Backend struct {
+	fs          *flag.FlagSet
+	args        []string
+	values      map[string]value
+	fields      []confita.Field
+	parsed      bool
+	usage       io.Writer
+	programName string
+}
+
+// Opt configures a Backend.
+type Opt func(*Backend)
+
+// WithUsage sets the writer the `-h`/`--help` output is written to. It
+// defaults to os.Stderr.
+func WithUsage(w io.Writer) Opt {
+	return func(b *Backend) {
+		b.usage = w
+	}
+}
+
+// WithProgramName overrides the program name shown in the `-h`/`--help`
+// output. It defaults to os.Args[0] for NewBackend and to fs.Name() for
+// NewBackendWithFlagSet.
+func WithProgramName(name string) Opt {
+	return func(b *Backend) {
+		b.programName = name
+	}
+}
+
+// NewBackend creates a flags backend that registers its own private
+// FlagSet and parses os.Args[1:].
+func NewBackend(opts ...Opt) *Backend {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	return newBackend(fs, os.Args[1:], opts...)
+}
+
+// NewBackendWithFlagSet creates a flags backend that registers confita's
+// derived flags onto fs instead of a private FlagSet, and parses args
+// instead of os.Args[1:]. This lets a host application that already
+// defines its own flags on fs add confita-tagged struct fields to that
+// same set, so the two coexist and get parsed together, and lets the
+// host pick fs's ErrorHandling.
+//
+// fs's own default usage output is suppressed the same way NewBackend
+// suppresses its private FlagSet's, so a `-h`/`--help` only ever prints
+// confita's grouped usage, not fs's raw one as well. opts default to
+// WithProgramName(fs.Name()), which callers can still override.
+func NewBackendWithFlagSet(fs *flag.FlagSet, args []string, opts ...Opt) *Backend {
+	fs.SetOutput(io.Discard)
+
+	return newBackend(fs, args, append([]Opt{WithProgramName(fs.Name())}, opts...)...)
+}
+
+func newBackend(fs *flag.FlagSet, args []string, opts ...Opt) *Backend {
+	b := &Backend{
+		fs:          fs,
+		args:        args,
+		values:      map[string]value{},
+		usage:       os.Stderr,
+		programName: os.Args[0],
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Name implements backend.Backend.
+func (b *Backend) Name() string {
+	return "flags"
+}
+
+// ResolveStruct implements confita.StructBackend. It registers every
+// field as a flag and, the first time it runs, parses the command line.
+//
+// Besides flag.ErrHelp, parsing is best effort: confita is often used
+// inside a program that has unrelated flags on os.Args (for instance go
+// test's own flags), and a parse error there should not take the whole
+// Load down. It just means none of our flags got set, so resolution
+// falls through to whatever backend comes next.
+func (b *Backend) ResolveStruct(ctx context.Context, fields []confita.Field) error {
+	for _, f := range fields {
+		b.register(f)
+	}
+
+	if b.parsed {
+		return nil
+	}
+	b.parsed = true
+	b.fields = fields
+
+	if err := b.fs.Parse(b.args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			usage.Write(b.usage, b.programName, b.fields)
+			return ErrHelpRequested
+		}
+	}
+
+	return nil
+}
+
+// register creates a flag.Value for f and binds it under f.Name and, if
+// set, f.Short.
+func (b *Backend) register(f confita.Field) {
+	if _, ok := b.values[f.Name]; ok {
+		return
+	}
+
+	var v value
+	switch f.Value.Kind() {
+	case reflect.Slice, reflect.Map:
+		v = &fieldvalue.Multi{}
+	default:
+		v = fieldvalue.NewScalar(f.Value.Kind() == reflect.Bool)
+	}
+
+	b.values[f.Name] = v
+	b.fs.Var(v, f.Name, "")
+	if f.Short != "" {
+		b.fs.Var(v, f.Short, "")
+	}
+}
+
+// Get implements backend.Backend. It returns backend.ErrNotFound when the
+// matching flag was never set on the command line, so that other
+// backends in the loader chain get a chance to provide the value.
+func (b *Backend) Get(ctx context.Context, key string) ([]byte, error) {
+	v, ok := b.values[key]
+	if !ok || !v.WasSet() {
+		return nil, backend.ErrNotFound
+	}
+
+	return []byte(v.String()), nil
+}
+
+// value is implemented by the flag.Value types used to back struct
+// fields, adding a way to tell whether the flag was actually passed on
+// the command line. fieldvalue.Scalar and fieldvalue.Multi, shared with
+// backend/flags/pflags, both satisfy it directly.
+type value interface {
+	flag.Value
+	WasSet() bool
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,6 +31,9 @@ type Config struct {
 	Edef uint          `config:"e-def,short=ed"`
 	F    float32       `config:"f"`
 	Fdef float32       `config:"f-def,short=fd"`
+
+	Hosts []string          `config:"hosts"`
+	Label map[string]string `config:"label"`
 }
 
 func runHelper(t *testing.T, args ...string) *Config {
@@ -93,6 +99,117 @@ func TestFlagsMixed(t *testing.T) {
 	require.Equal(t, float32(42.42), cfg.Fdef)
 }
 
+func TestFlagsSliceAndMap(t *testing.T) {
+	t.Run("comma separated", func(t *testing.T) {
+		cfg := runHelper(t, "-hosts=a,b,c", "-label=env=prod,team=core")
+		require.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+		require.Equal(t, map[string]string{"env": "prod", "team": "core"}, cfg.Label)
+	})
+
+	t.Run("repeated flag", func(t *testing.T) {
+		cfg := runHelper(t, "-hosts=a", "-hosts=b", "-label=env=prod", "-label=team=core")
+		require.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		require.Equal(t, map[string]string{"env": "prod", "team": "core"}, cfg.Label)
+	})
+}
+
+func TestFlagsBareBool(t *testing.T) {
+	cfg := runHelper(t, "-b")
+	require.Equal(t, true, cfg.B)
+}
+
+func TestNewBackendWithFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	other := fs.String("other", "", "a flag the host app defined itself")
+
+	var cfg struct {
+		A string `config:"a"`
+		B int    `config:"b,short=bd"`
+	}
+
+	b := NewBackendWithFlagSet(fs, []string{"-other=host-value", "-a=hello", "-bd=42"})
+	err := confita.NewLoader(b).Load(context.Background(), &cfg)
+	require.NoError(t, err)
+	require.Equal(t, "host-value", *other)
+	require.Equal(t, "hello", cfg.A)
+	require.Equal(t, 42, cfg.B)
+}
+
+func TestNewBackendWithFlagSetHelp(t *testing.T) {
+	fs := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	fs.String("other", "", "a flag the host app defined itself")
+
+	var cfg helpConfig
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+
+	b := NewBackendWithFlagSet(fs, []string{"-h"}, WithUsage(&out))
+	err := confita.NewLoader(b).Load(context.Background(), &cfg)
+	require.ErrorIs(t, err, ErrHelpRequested)
+
+	output := out.String()
+	require.Contains(t, output, "Usage of myapp")
+	require.Contains(t, output, "--name, -n")
+	require.Contains(t, output, "the name of the service")
+	require.Contains(t, output, "Network:")
+	require.Contains(t, output, "--port")
+
+	// fs's own default usage output must be suppressed: its raw "-other"
+	// entry (and a second "Usage of" header) would only appear if
+	// NewBackendWithFlagSet failed to call fs.SetOutput(io.Discard).
+	require.Equal(t, 1, strings.Count(output, "Usage of"))
+	require.NotContains(t, output, "-other")
+}
+
+type helpConfig struct {
+	Name string `config:"name,short=n,description=the name of the service"`
+	Port int    `config:"port,group=Network,description=the port to listen on"`
+}
+
+func TestFlagsHelp(t *testing.T) {
+	cs := []string{"-test.run=TestHelperHelpProcess", "--", "-h"}
+	cmd := exec.Command(os.Args[0], cs...)
+	cmd.Env = []string{"GO_HELPER_PROCESS=1"}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	require.NoError(t, err)
+	require.Contains(t, stderr.String(), "Usage of")
+	require.Contains(t, stderr.String(), "--name, -n")
+	require.Contains(t, stderr.String(), "the name of the service")
+	require.Contains(t, stderr.String(), "Network:")
+	require.Contains(t, stderr.String(), "--port")
+}
+
+func TestHelperHelpProcess(t *testing.T) {
+	if os.Getenv("GO_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 {
+		if args[0] == "--" {
+			args = args[1:]
+			break
+		}
+		args = args[1:]
+	}
+
+	os.Args = append(os.Args[:1], args...)
+
+	var cfg helpConfig
+
+	err := confita.NewLoader(NewBackend()).Load(context.Background(), &cfg)
+	if !errors.Is(err, ErrHelpRequested) {
+		fmt.Fprintf(os.Stderr, "expected ErrHelpRequested, got %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
 func TestHelperProcess(t *testing.T) {
 	if os.Getenv("GO_HELPER_PROCESS") != "1" {
 		return
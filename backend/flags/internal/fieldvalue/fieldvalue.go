@@ -0,0 +1,70 @@
+// Package fieldvalue holds the flag-value bookkeeping shared by
+// backend/flags and backend/flags/pflags: storing the raw flag text and
+// deferring its parsing into the target struct field's type to confita,
+// which already knows how to do that for every backend.
+package fieldvalue
+
+import "strings"
+
+// Scalar backs every scalar field kind (string, bool, duration, int,
+// uint, float).
+type Scalar struct {
+	raw    string
+	set    bool
+	isBool bool
+}
+
+// NewScalar creates a Scalar for a field. isBool should be true for bool
+// fields, so the flag can be given as a bare `-flag`/`--flag` with no
+// explicit value.
+func NewScalar(isBool bool) *Scalar {
+	return &Scalar{isBool: isBool}
+}
+
+func (s *Scalar) String() string {
+	return s.raw
+}
+
+func (s *Scalar) Set(raw string) error {
+	s.raw = raw
+	s.set = true
+	return nil
+}
+
+// WasSet reports whether Set was ever called, i.e. whether the flag was
+// actually passed on the command line.
+func (s *Scalar) WasSet() bool {
+	return s.set
+}
+
+// IsBoolFlag tells the flag/pflag packages that a bare `-flag`, with no
+// `=value`, is valid and means true, same as their own bool flags.
+func (s *Scalar) IsBoolFlag() bool {
+	return s.isBool
+}
+
+// Multi backs []T and map[K]V fields. It accepts either a single
+// comma-separated flag (`--hosts=a,b,c`) or repeated occurrences of the
+// same flag (`--hosts=a --hosts=b`), and joins them back into a single
+// comma-separated string so confita's generic slice/map parsing can take
+// over from there.
+type Multi struct {
+	parts []string
+	set   bool
+}
+
+func (m *Multi) String() string {
+	return strings.Join(m.parts, ",")
+}
+
+func (m *Multi) Set(raw string) error {
+	m.parts = append(m.parts, raw)
+	m.set = true
+	return nil
+}
+
+// WasSet reports whether Set was ever called, i.e. whether the flag was
+// actually passed on the command line.
+func (m *Multi) WasSet() bool {
+	return m.set
+}
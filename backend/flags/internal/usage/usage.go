@@ -0,0 +1,56 @@
+// Package usage renders the `-h`/`--help` output shared by backend/flags
+// and backend/flags/pflags, so the two stay in lockstep.
+package usage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/HnH/confita"
+)
+
+// Write writes a formatted usage message to w, listing every field
+// grouped by its `group=` tag option, in the order: ungrouped fields
+// first, then each group in alphabetical order.
+func Write(w io.Writer, programName string, fields []confita.Field) {
+	groups := map[string][]confita.Field{}
+	var groupNames []string
+	for _, f := range fields {
+		if _, ok := groups[f.Group]; !ok && f.Group != "" {
+			groupNames = append(groupNames, f.Group)
+		}
+		groups[f.Group] = append(groups[f.Group], f)
+	}
+	sort.Strings(groupNames)
+
+	fmt.Fprintf(w, "Usage of %s:\n", programName)
+
+	printGroup := func(title string, fields []confita.Field) {
+		if len(fields) == 0 {
+			return
+		}
+
+		if title != "" {
+			fmt.Fprintf(w, "\n%s:\n", title)
+		} else {
+			fmt.Fprintln(w)
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, f := range fields {
+			name := "--" + f.Name
+			if f.Short != "" {
+				name += ", -" + f.Short
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t(default %q)\t%s\n", name, f.Value.Type(), f.Default(), f.Description)
+		}
+		tw.Flush()
+	}
+
+	printGroup("", groups[""])
+	for _, name := range groupNames {
+		printGroup(name, groups[name])
+	}
+}
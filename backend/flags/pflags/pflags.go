@@ -0,0 +1,184 @@
+// Package pflags implements a confita backend that reads configuration
+// from command line flags registered on a github.com/spf13/pflag.FlagSet,
+// for programs already built around pflag or cobra.
+package pflags
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/spf13/pflag"
+
+	"github.com/HnH/confita"
+	"github.com/HnH/confita/backend"
+	"github.com/HnH/confita/backend/flags/internal/fieldvalue"
+	"github.com/HnH/confita/backend/flags/internal/usage"
+)
+
+// ErrHelpRequested is returned by Load when the command line asked for
+// help (`-h`/`--help`). The usage message has already been written to
+// the backend's usage writer by the time callers see it; they should
+// treat it as a signal to exit cleanly rather than as a failure.
+var ErrHelpRequested = errors.New("pflags: help requested")
+
+// Backend is a confita backend that resolves keys from command line
+// flags registered on a pflag.FlagSet. It implements
+// confita.StructBackend: on Load it registers one flag per tagged
+// struct field onto fs and parses args before any key is resolved.
+//
+// A field's `short=` tag option is only honored as a pflag shorthand
+// when it is exactly one ASCII character, since that's all pflag
+// supports; longer aliases are silently registered as long flags only.
+type Backend struct {
+	fs          *pflag.FlagSet
+	args        []string
+	values      map[string]value
+	fields      []confita.Field
+	parsed      bool
+	usage       io.Writer
+	programName string
+}
+
+// Opt configures a Backend.
+type Opt func(*Backend)
+
+// WithUsage sets the writer the `-h`/`--help` output is written to. It
+// defaults to os.Stderr.
+func WithUsage(w io.Writer) Opt {
+	return func(b *Backend) {
+		b.usage = w
+	}
+}
+
+// WithProgramName overrides the program name shown in the `-h`/`--help`
+// output. It defaults to fs.Name().
+func WithProgramName(name string) Opt {
+	return func(b *Backend) {
+		b.programName = name
+	}
+}
+
+// NewBackend creates a pflags backend that registers confita's derived
+// flags onto fs instead of a private FlagSet, and parses args. This lets
+// a host application that already defines its own flags on fs (directly
+// via pflag, or through cobra) add confita-tagged struct fields to that
+// same set.
+//
+// fs's own default usage output is suppressed, same as backend/flags
+// does for its FlagSet: on `-h`/`--help` this backend writes confita's
+// own grouped usage to its usage writer (os.Stderr unless WithUsage
+// sets another) instead, so the two don't both fire.
+// opts default to WithProgramName(fs.Name()), which callers can still
+// override.
+func NewBackend(fs *pflag.FlagSet, args []string, opts ...Opt) *Backend {
+	fs.SetOutput(io.Discard)
+
+	b := &Backend{
+		fs:          fs,
+		args:        args,
+		values:      map[string]value{},
+		usage:       os.Stderr,
+		programName: fs.Name(),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Name implements backend.Backend.
+func (b *Backend) Name() string {
+	return "pflags"
+}
+
+// ResolveStruct implements confita.StructBackend.
+//
+// Besides pflag.ErrHelp, parsing is best effort: a parse error does not
+// take the whole Load down, it just means none of our flags got set, so
+// resolution falls through to whatever backend comes next.
+func (b *Backend) ResolveStruct(ctx context.Context, fields []confita.Field) error {
+	for _, f := range fields {
+		b.register(f)
+	}
+
+	if b.parsed {
+		return nil
+	}
+	b.parsed = true
+	b.fields = fields
+
+	if err := b.fs.Parse(b.args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			usage.Write(b.usage, b.programName, b.fields)
+			return ErrHelpRequested
+		}
+	}
+
+	return nil
+}
+
+func (b *Backend) register(f confita.Field) {
+	if _, ok := b.values[f.Name]; ok {
+		return
+	}
+
+	var v value
+	switch f.Value.Kind() {
+	case reflect.Slice, reflect.Map:
+		v = multiValue{&fieldvalue.Multi{}}
+	default:
+		v = scalarValue{fieldvalue.NewScalar(f.Value.Kind() == reflect.Bool)}
+	}
+
+	b.values[f.Name] = v
+
+	shorthand := f.Short
+	if len(shorthand) != 1 {
+		shorthand = ""
+	}
+	pfs := []*pflag.Flag{b.fs.VarPF(v, f.Name, shorthand, "")}
+	if f.Short != "" && shorthand == "" {
+		pfs = append(pfs, b.fs.VarPF(v, f.Short, "", ""))
+	}
+	if sv, ok := v.(scalarValue); ok && sv.IsBoolFlag() {
+		for _, pf := range pfs {
+			pf.NoOptDefVal = "true"
+		}
+	}
+}
+
+// Get implements backend.Backend. It returns backend.ErrNotFound when
+// the matching flag was never set on the command line, so that other
+// backends in the loader chain get a chance to provide the value.
+func (b *Backend) Get(ctx context.Context, key string) ([]byte, error) {
+	v, ok := b.values[key]
+	if !ok || !v.WasSet() {
+		return nil, backend.ErrNotFound
+	}
+
+	return []byte(v.String()), nil
+}
+
+// value is implemented by the pflag.Value types used to back struct
+// fields, adding a way to tell whether the flag was actually passed on
+// the command line.
+type value interface {
+	pflag.Value
+	WasSet() bool
+}
+
+// scalarValue and multiValue adapt the flag.Value types shared with
+// backend/flags (via fieldvalue) to pflag.Value, which additionally
+// requires a Type method.
+type scalarValue struct{ *fieldvalue.Scalar }
+
+func (scalarValue) Type() string { return "string" }
+
+type multiValue struct{ *fieldvalue.Multi }
+
+func (multiValue) Type() string { return "stringSlice" }
@@ -0,0 +1,74 @@
+package pflags
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HnH/confita"
+)
+
+func TestPflags(t *testing.T) {
+	fs := pflag.NewFlagSet("myapp", pflag.ContinueOnError)
+	other := fs.StringP("other", "o", "", "a flag the host app defined itself")
+
+	var cfg struct {
+		A     string   `config:"a,short=a"`
+		Hosts []string `config:"hosts"`
+	}
+
+	b := NewBackend(fs, []string{"-o=host-value", "--a=hello", "--hosts=x,y,z"})
+	err := confita.NewLoader(b).Load(context.Background(), &cfg)
+	require.NoError(t, err)
+	require.Equal(t, "host-value", *other)
+	require.Equal(t, "hello", cfg.A)
+	require.Equal(t, []string{"x", "y", "z"}, cfg.Hosts)
+}
+
+func TestPflagsHelp(t *testing.T) {
+	fs := pflag.NewFlagSet("myapp", pflag.ContinueOnError)
+	fs.StringP("other", "o", "", "a flag the host app defined itself")
+
+	var cfg struct {
+		Name string `config:"name,short=n,description=the name of the service"`
+		Port int    `config:"port,group=Network,description=the port to listen on"`
+	}
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+
+	b := NewBackend(fs, []string{"-h"}, WithUsage(&out))
+
+	err := confita.NewLoader(b).Load(context.Background(), &cfg)
+	require.ErrorIs(t, err, ErrHelpRequested)
+
+	output := out.String()
+	require.Contains(t, output, "Usage of myapp")
+	require.Contains(t, output, "--name, -n")
+	require.Contains(t, output, "the name of the service")
+	require.Contains(t, output, "Network:")
+	require.Contains(t, output, "--port")
+
+	// fs's own default FlagUsages output must be suppressed: its raw
+	// "-o, --other" entry would only appear if NewBackend failed to
+	// call fs.SetOutput(io.Discard).
+	require.Equal(t, 1, strings.Count(output, "Usage of"))
+	require.NotContains(t, output, "--other")
+}
+
+func TestPflagsBareBool(t *testing.T) {
+	fs := pflag.NewFlagSet("myapp", pflag.ContinueOnError)
+
+	var cfg struct {
+		Verbose bool `config:"verbose"`
+	}
+
+	b := NewBackend(fs, []string{"--verbose", "positional-arg"})
+	err := confita.NewLoader(b).Load(context.Background(), &cfg)
+	require.NoError(t, err)
+	require.Equal(t, true, cfg.Verbose)
+	require.Equal(t, []string{"positional-arg"}, fs.Args())
+}
@@ -0,0 +1,301 @@
+// Package confita loads configuration from multiple backends and stores
+// it into a struct described by `config` tags.
+package confita
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HnH/confita/backend"
+)
+
+const tagName = "config"
+
+// Field describes a single struct field tagged with `config:"..."`,
+// together with the options parsed out of its tag. It is handed to
+// backends that need to see the whole struct before resolving individual
+// keys, such as backend/flags which must register every field on a
+// flag.FlagSet before the command line can be parsed.
+type Field struct {
+	// Name is the key used to look the value up in a backend.
+	Name string
+	// Short is the alternate, short alias for Name, taken from the
+	// `short=` tag option. It is empty when no alias was given.
+	Short string
+	// Description documents the field, taken from the `description=` or
+	// `desc=` tag option. Backends that generate help output use it.
+	Description string
+	// Group names the section this field belongs to in help output,
+	// taken from the `group=` tag option. Fields without one are
+	// ungrouped.
+	Group string
+	// Required makes Load fail when no backend can provide a value.
+	Required bool
+	// Value is addressable and holds the field's value. Before Load
+	// runs it holds whatever the caller pre-populated the struct with,
+	// which backends may use as a default.
+	Value reflect.Value
+}
+
+// Default formats the field's current value with fmt's %v verb, so
+// backends that call it before Load can show the pre-Load value as a
+// default.
+func (f Field) Default() string {
+	return fmt.Sprintf("%v", f.Value.Interface())
+}
+
+// StructBackend is implemented by backends that need access to the full
+// set of tagged fields before they can resolve individual keys.
+type StructBackend interface {
+	backend.Backend
+	ResolveStruct(ctx context.Context, fields []Field) error
+}
+
+// Loader loads configuration keys from backends and stores them in a
+// struct.
+type Loader struct {
+	backends []backend.Backend
+}
+
+// NewLoader creates a configuration loader that reads from the given
+// backends, in order: the first backend able to provide a value for a
+// key wins.
+func NewLoader(backends ...backend.Backend) *Loader {
+	return &Loader{
+		backends: backends,
+	}
+}
+
+// Load analyzes all the struct fields tagged with `config` and queries
+// the backends, in order, until one of them returns a value.
+func (l *Loader) Load(ctx context.Context, to interface{}) error {
+	s := reflect.ValueOf(to)
+	if s.Kind() != reflect.Ptr || s.Elem().Kind() != reflect.Struct {
+		return errors.New("confita: 'to' must be a pointer to a struct")
+	}
+
+	fields, err := parseStruct(s.Elem())
+	if err != nil {
+		return err
+	}
+
+	for _, b := range l.backends {
+		if sb, ok := b.(StructBackend); ok {
+			if err := sb.ResolveStruct(ctx, fields); err != nil {
+				return fmt.Errorf("confita: backend %q: %w", b.Name(), err)
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if err := l.resolveField(ctx, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Loader) resolveField(ctx context.Context, f Field) error {
+	for _, b := range l.backends {
+		data, err := b.Get(ctx, f.Name)
+		if err != nil {
+			if errors.Is(err, backend.ErrNotFound) {
+				continue
+			}
+			return fmt.Errorf("confita: backend %q: %w", b.Name(), err)
+		}
+
+		return setValue(f.Value, data)
+	}
+
+	if f.Required {
+		return fmt.Errorf("confita: required key %q was not found in any backend", f.Name)
+	}
+
+	return nil
+}
+
+// parseStruct walks the exported fields of s and returns the ones tagged
+// with `config`. Untagged struct fields are recursed into so that
+// configuration can be grouped into nested structs.
+func parseStruct(s reflect.Value) ([]Field, error) {
+	var fields []Field
+
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		fv := s.Field(i)
+
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok {
+			if fv.Kind() == reflect.Struct {
+				nested, err := parseStruct(fv)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+			}
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		f, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("confita: field %q: %w", sf.Name, err)
+		}
+
+		f.Value = fv
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// parseTag parses the comma separated `config` tag grammar:
+//
+//	name[,short=x][,required][,description=x][,group=x]
+func parseTag(tag string) (Field, error) {
+	parts := strings.Split(tag, ",")
+
+	f := Field{Name: strings.TrimSpace(parts[0])}
+	if f.Name == "" {
+		return f, errors.New("missing key name")
+	}
+
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		switch {
+		case opt == "required":
+			f.Required = true
+		case strings.HasPrefix(opt, "short="):
+			f.Short = strings.TrimPrefix(opt, "short=")
+		case strings.HasPrefix(opt, "description="):
+			f.Description = strings.TrimPrefix(opt, "description=")
+		case strings.HasPrefix(opt, "desc="):
+			f.Description = strings.TrimPrefix(opt, "desc=")
+		case strings.HasPrefix(opt, "group="):
+			f.Group = strings.TrimPrefix(opt, "group=")
+		case opt == "":
+		default:
+			return f, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+
+	return f, nil
+}
+
+// setValue parses data and stores it into v, which must be addressable.
+// It supports the scalar kinds confita has always supported, plus slices
+// and maps: slices are populated from a comma separated list, maps from
+// a comma separated list of key=value pairs.
+func setValue(v reflect.Value, data []byte) error {
+	raw := string(data)
+
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("confita: invalid duration %q: %w", raw, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Kind() == reflect.Slice:
+		return setSlice(v, raw)
+	case v.Kind() == reflect.Map:
+		return setMap(v, raw)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("confita: invalid bool %q: %w", raw, err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("confita: invalid int %q: %w", raw, err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("confita: invalid uint %q: %w", raw, err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("confita: invalid float %q: %w", raw, err)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("confita: unsupported field type %s", v.Type())
+	}
+
+	return nil
+}
+
+func setSlice(v reflect.Value, raw string) error {
+	if raw == "" {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		return nil
+	}
+
+	parts := strings.Split(raw, ",")
+	sl := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+	for i, p := range parts {
+		if err := setValue(sl.Index(i), []byte(strings.TrimSpace(p))); err != nil {
+			return err
+		}
+	}
+
+	v.Set(sl)
+	return nil
+}
+
+func setMap(v reflect.Value, raw string) error {
+	m := reflect.MakeMap(v.Type())
+	if raw == "" {
+		v.Set(m)
+		return nil
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("confita: invalid map entry %q, want key=value", pair)
+		}
+
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := setValue(key, []byte(strings.TrimSpace(kv[0]))); err != nil {
+			return err
+		}
+
+		val := reflect.New(v.Type().Elem()).Elem()
+		if err := setValue(val, []byte(strings.TrimSpace(kv[1]))); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(key, val)
+	}
+
+	v.Set(m)
+	return nil
+}